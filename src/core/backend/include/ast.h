@@ -16,6 +16,11 @@ enum NodeType
 	Identifier,
 	Literal,
 	Operator,
+	Return,
+	Loop,
+	Break,
+	Continue,
+	List,
 };
 
 struct Token
@@ -46,6 +51,11 @@ inline std::string nodeTypeString(NodeType type) {
 		"Identifier",
 		"Literal",
 		"Operator",
+		"Return",
+		"Loop",
+		"Break",
+		"Continue",
+		"List",
 	};
 	return vals[type];
 }
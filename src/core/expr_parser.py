@@ -10,27 +10,61 @@ class ExprParser:
             Rule("MULTIPLY", Precedence.Factor, infix=self.binary),
             Rule("DIVIDE", Precedence.Factor, infix=self.binary),
             Rule("EQUALS", Precedence.Assignment, infix=self.binary),
-            Rule("NE", Precedence.Zero, prefix=self.unary),
+            Rule("DEQ", Precedence.Equality, infix=self.binary),
+            Rule("NE", Precedence.Equality, prefix=self.unary, infix=self.binary),
+            Rule("LT", Precedence.Comparison, infix=self.binary),
+            Rule("GT", Precedence.Comparison, infix=self.binary),
+            Rule("LTE", Precedence.Comparison, infix=self.binary),
+            Rule("GTE", Precedence.Comparison, infix=self.binary),
+            Rule("PIPE", Precedence.Pipe, infix=self.pipe),
+            Rule("DOTDOT", Precedence.Range, infix=self.binary),
+            Rule("DOTDOTEQ", Precedence.Range, infix=self.binary),
             Rule("INT", Precedence.Zero, prefix=self.number),
             Rule("FLOAT", Precedence.Zero, prefix=self.number),
+            Rule("STR", Precedence.Zero, prefix=self.string),
             Rule("ID", Precedence.Zero, prefix=self.identifier),
             Rule("LPAR", Precedence.Zero, prefix=self.paren),
+            Rule("AT", Precedence.Zero, prefix=self.call),
+            Rule("LBRACE", Precedence.Zero, prefix=self.list),
         ]
 
     def rule(self, tok_type):
-        return next(filter(lambda x: x.type == tok_type, self.rules))
+        return next(filter(lambda x: x.type == tok_type, self.rules), None)
+
+    def precedence_table(self):
+        # Binding power of every infix operator, keyed by token type
+        return {rule.type: rule.precedence for rule in self.rules if rule.infix}
 
     def paren(self):
         expr = self.parse()
         self.parser.expect("RPAR")
         return expr
 
+    def list(self):
+        node = TreeNode(NodeType.List, self.parser.prev)
+
+        # A trailing comma before the closing bracket is allowed
+        while self.parser.cur.type != "RBRACE":
+            node.children.append(self.parse())
+            if self.parser.cur.type != "COMMA":
+                break
+            self.parser.consume()
+
+        self.parser.expect("RBRACE")
+        return node
+
+    def call(self):
+        return self.parser.function_call()
+
     def identifier(self):
         return TreeNode(NodeType.Identifier, self.parser.prev)
 
     def number(self):
         return TreeNode(NodeType.Literal, self.parser.prev)
 
+    def string(self):
+        return TreeNode(NodeType.Literal, self.parser.prev)
+
     def unary(self):
         node = TreeNode(NodeType.Operator, self.parser.prev)
         node.children.append(self.parse(precedence=Precedence.Unary))
@@ -42,19 +76,37 @@ class ExprParser:
         node.children.extend([left, self.parse(precedence=rule.precedence + 1)])
         return node
 
+    def pipe(self, left):
+        node = self.binary(left)
+
+        # The piped value becomes the first argument of the target, so
+        # only a function name or an @ call can stand on the right
+        if node.children[1].type not in [NodeType.Identifier, NodeType.FunctionCall]:
+            raise Exception(
+                f"Expected a function name or @ call after '|' at line {node.tok.lineno}, column {node.tok.col}."
+            )
+
+        return node
+
     def parse(self, precedence=Precedence.Assignment):
-        prefix = self.rule(self.parser.consume().type).prefix
+        tok = self.parser.consume()
+        rule = self.rule(tok.type)
+
+        if not rule or not rule.prefix:
+            raise Exception(
+                f"Expected expression at line {tok.lineno}, column {tok.col}."
+            )
 
-        if not prefix:
-            raise Exception("Expected expression.")
+        expr = rule.prefix()
 
-        expr = prefix()
+        # Stop at any token that can't continue the expression,
+        # e.g. the ELSE in `if c: a else: b`
+        while self.parser.cur.type not in ["RPAR", "RBRACE", "NEWLINE", "COMMA", "COLON"]:
+            rule = self.rule(self.parser.cur.type)
+            if not rule or not rule.infix or rule.precedence < precedence:
+                break
 
-        while (
-            self.parser.cur.type not in ["RPAR", "NEWLINE", "COMMA", "COLON"]
-            and self.rule(self.parser.cur.type).precedence >= precedence
-        ):
-            infix = self.rule(self.parser.consume().type).infix
-            expr = infix(expr)
+            self.parser.consume()
+            expr = rule.infix(expr)
 
         return expr
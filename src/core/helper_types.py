@@ -17,6 +17,11 @@ class NodeType(Enum):
     Identifier = 8
     Literal = 9
     Operator = 10
+    Return = 11
+    Loop = 12
+    Break = 13
+    Continue = 14
+    List = 15
 
     def __str__(self):
         return self._name_
@@ -26,9 +31,22 @@ class NodeType(Enum):
 class Precedence:
     Zero = 0
     Assignment = 1
-    Term = 2
-    Factor = 3
-    Unary = 4
+    Equality = 2
+    Comparison = 3
+    Pipe = 4
+    Range = 5
+    Term = 6
+    Factor = 7
+    Unary = 8
+
+
+# 1-based display column of pos in source. Tabs advance to the next
+# multiple of tab_width; characters count as one column each.
+def column(source, pos, tab_width=4):
+    col = 0
+    for c in source[source.rfind("\n", 0, pos) + 1 : pos]:
+        col = (col // tab_width + 1) * tab_width if c == "\t" else col + 1
+    return col + 1
 
 
 @dataclass
@@ -0,0 +1,199 @@
+from core.helper_types import *
+
+
+class ReturnSignal(Exception):
+    # Unwinds the Python stack from a 'return' up to the enclosing call
+    def __init__(self, value):
+        self.value = value
+
+
+class BreakSignal(Exception):
+    pass
+
+
+class ContinueSignal(Exception):
+    pass
+
+
+def edit_distance(a, b):
+    # Levenshtein distance, one row of the table at a time
+    row = list(range(len(b) + 1))
+    for i, ca in enumerate(a, 1):
+        prev, row[0] = row[0], i
+        for j, cb in enumerate(b, 1):
+            prev, row[j] = row[j], min(row[j] + 1, row[j - 1] + 1, prev + (ca != cb))
+    return row[-1]
+
+
+@dataclass
+class Closure:
+    # A function definition together with the frames it was defined in
+    node: TreeNode
+    env: list
+
+
+class QuarkInterpreter:
+    def __init__(self, args=None) -> None:
+        self.scopes = [{"args": args or []}]
+
+    def lookup(self, name):
+        for scope in reversed(self.scopes):
+            if name in scope:
+                return scope[name]
+
+        raise NameError(f"Undefined identifier '{name}'.{self.suggest(name)}")
+
+    def suggest(self, name, functions=False):
+        # Names within a third of their length in edits count as typos
+        names = {
+            key
+            for scope in self.scopes
+            for key, val in scope.items()
+            if not functions or isinstance(val, Closure)
+        }
+        best = min(sorted(names), key=lambda key: edit_distance(name, key), default=None)
+        if best is None or edit_distance(name, best) > max(1, len(name) // 3):
+            return ""
+        return f" Did you mean '{best}'?"
+
+    def call(self, name, args):
+        fn = next((scope[name] for scope in reversed(self.scopes) if name in scope), None)
+        if fn is None:
+            raise NameError(f"Undefined function '{name}'.{self.suggest(name, functions=True)}")
+        if not isinstance(fn, Closure):
+            raise TypeError(f"'{name}' is not a function.")
+
+        params = [param.tok.value for param in fn.node.children[1].children]
+        if len(params) != len(args):
+            raise TypeError(
+                f"Function '{name}' expects {len(params)} arguments but got {len(args)}."
+            )
+
+        # Scoping is lexical: the callee sees its own frame and the
+        # frames it was defined in, never the frames of its caller
+        saved, self.scopes = self.scopes, fn.env + [dict(zip(params, args))]
+        try:
+            return self.evaluate(fn.node.children[2])
+        except ReturnSignal as ret:
+            return ret.value
+        finally:
+            self.scopes = saved
+
+    def evaluate(self, node):
+        if node.type in [NodeType.CompilationUnit, NodeType.Block]:
+            result = None
+            for child in node.children:
+                result = self.evaluate(child)
+            return result
+
+        if node.type == NodeType.Function:
+            # The frames are shared, not copied, so the function sees later
+            # definitions in them, including its own name for recursion
+            self.scopes[-1][node.children[0].tok.value] = Closure(node, list(self.scopes))
+            return None
+
+        if node.type == NodeType.Condition:
+            if self.evaluate(node.children[0]):
+                return self.evaluate(node.children[1])
+            if len(node.children) == 3:
+                return self.evaluate(node.children[2])
+            return None
+
+        if node.type == NodeType.Loop:
+            while self.evaluate(node.children[0]):
+                try:
+                    self.evaluate(node.children[1])
+                except BreakSignal:
+                    break
+                except ContinueSignal:
+                    continue
+            return None
+
+        if node.type == NodeType.Break:
+            raise BreakSignal()
+
+        if node.type == NodeType.Continue:
+            raise ContinueSignal()
+
+        if node.type == NodeType.Return:
+            raise ReturnSignal(
+                self.evaluate(node.children[0]) if node.children else None
+            )
+
+        if node.type == NodeType.FunctionCall:
+            args = [self.evaluate(arg) for arg in node.children[1].children]
+            return self.call(node.children[0].tok.value, args)
+
+        if node.type == NodeType.Operator:
+            if len(node.children) == 1:
+                operand = self.evaluate(node.children[0])
+
+                match node.tok.type:
+                    case "MINUS":
+                        return -operand
+                    case _:
+                        raise Exception(f"Unsupported unary operator '{node.tok.value}'.")
+
+            if node.tok.type == "EQUALS":
+                val = self.evaluate(node.children[1])
+                self.scopes[-1][node.children[0].tok.value] = val
+                return val
+
+            if node.tok.type == "PIPE":
+                val, target = self.evaluate(node.children[0]), node.children[1]
+                if target.type == NodeType.Identifier:
+                    return self.call(target.tok.value, [val])
+
+                args = [self.evaluate(arg) for arg in target.children[1].children]
+                return self.call(target.children[0].tok.value, [val] + args)
+
+            lhs = self.evaluate(node.children[0])
+            rhs = self.evaluate(node.children[1])
+
+            match node.tok.type:
+                case "PLUS":
+                    return lhs + rhs
+                case "MINUS":
+                    return lhs - rhs
+                case "MULTIPLY":
+                    return lhs * rhs
+                case "DIVIDE":
+                    # Integer division truncates toward zero like the
+                    # sdiv emitted by codegen, not Python's floor division
+                    if isinstance(lhs, int) and isinstance(rhs, int):
+                        quotient = abs(lhs) // abs(rhs)
+                        return quotient if (lhs < 0) == (rhs < 0) else -quotient
+                    return lhs / rhs
+                case "DEQ":
+                    return lhs == rhs
+                case "NE":
+                    return lhs != rhs
+                case "LT":
+                    return lhs < rhs
+                case "GT":
+                    return lhs > rhs
+                case "LTE":
+                    return lhs <= rhs
+                case "GTE":
+                    return lhs >= rhs
+                case "DOTDOT" | "DOTDOTEQ":
+                    # bool is an int subclass in Python but not in Quark
+                    if not all(type(val) is int for val in [lhs, rhs]):
+                        raise TypeError(
+                            f"Range bounds must be integers at line {node.tok.lineno}, column {node.tok.col}."
+                        )
+                    end = rhs + 1 if node.tok.type == "DOTDOTEQ" else rhs
+                    return list(range(lhs, end))
+                case _:
+                    raise Exception(f"Unsupported operator '{node.tok.value}'.")
+
+        if node.type == NodeType.List:
+            return [self.evaluate(child) for child in node.children]
+
+        if node.type == NodeType.Identifier:
+            return self.lookup(node.tok.value)
+
+        if node.type == NodeType.Literal:
+            return node.tok.value
+
+        raise Exception(f"Cannot evaluate node {node}.")
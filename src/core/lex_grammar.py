@@ -1,3 +1,6 @@
+import re
+from core.helper_types import column
+
 reserved = {
     "use": "USE",
     "module": "MODULE",
@@ -6,10 +9,14 @@ reserved = {
     "or": "OR",
     "if": "IF",
     "elif": "ELIF",
+    "elseif": "ELIF",
     "else": "ELSE",
     "for": "FOR",
     "while": "WHILE",
+    "break": "BREAK",
+    "continue": "CONTINUE",
     "fn": "FN",
+    "return": "RETURN",
     "class": "CLASS",
 }
 
@@ -40,6 +47,8 @@ tokens = (
     "STR",  # str
     "AT",   # @
     "DOT",  # .
+    "DOTDOT",  # ..
+    "DOTDOTEQ",  # ..=
     "COMMA",  # ,
     "QUOTES",  # '
     "DQUOTES",  # "
@@ -70,10 +79,9 @@ t_GTE = r"\>\="
 t_DEQ = r"\=\="
 t_NE = r"\!\="
 
-t_LBRACE = r"\["
-t_RBRACE = r"\]"
-
 t_DOT = r"\."
+t_DOTDOT = r"\.\."
+t_DOTDOTEQ = r"\.\.\="
 t_AT = r"@"
 t_COMMA = r"\,"
 t_QUOTES = r"\'"
@@ -90,11 +98,62 @@ def t_ID(t):
 
 
 # Data Types
-t_STR = r'"([^"\n]|(\\"))*"'
+ESCAPES = {"n": "\n", "t": "\t", "r": "\r", "0": "\0", "\\": "\\", '"': '"', "'": "'"}
+
+
+def t_STR(t):
+    r'"([^"\\\n]|\\.)*"'
+    t.value = unescape(t, t.value[1:-1])
+    return t
+
+
+# Decodes the escapes in a string literal, warning about (and keeping
+# verbatim) any it doesn't know. Supports \xNN and \u{...} as well.
+def unescape(t, text):
+    out, i = [], 0
+
+    while i < len(text):
+        if text[i] != "\\":
+            out.append(text[i])
+            i += 1
+            continue
+
+        # Column of the backslash, past the opening quote
+        col = column(t.lexer.lexdata, t.pos + 1 + i, t.lexer.tab_width)
+        c = text[i + 1]
+        if c in ESCAPES:
+            out.append(ESCAPES[c])
+            i += 2
+        elif c == "x" and re.fullmatch(r"[0-9a-fA-F]{2}", text[i + 2 : i + 4]):
+            out.append(chr(int(text[i + 2 : i + 4], 16)))
+            i += 4
+        elif c == "u" and (m := re.match(r"\{([0-9a-fA-F]{1,6})\}", text[i + 2 :])):
+            code = int(m.group(1), 16)
+            if code > 0x10FFFF:
+                print(f"Invalid code point '\\u{{{m.group(1)}}}' at line {t.lineno}, column {col}")
+            else:
+                out.append(chr(code))
+            i += 2 + len(m.group(0))
+        else:
+            print(f"Unknown escape sequence '\\{c}' at line {t.lineno}, column {col}")
+            out.append(text[i : i + 2])
+            i += 2
+
+    return "".join(out)
+
+
+# Raw strings are delimited by backticks, may span lines and
+# never interpret backslashes or quotes
+def t_raw_string(t):
+    r"`[^`]*`"
+    t.type = "STR"
+    t.value = t.value[1:-1]
+    t.lexer.lineno += t.value.count("\n")
+    return t
 
 
 def t_FLOAT(t):
-    r"(\d*\.\d+)|(\d+\.\d*)"
+    r"(\d*\.\d+)|(\d+\.(?!\.)\d*)"
     t.value = float(t.value)
     return t
 
@@ -119,23 +178,69 @@ def t_RPAR(t):
     return t
 
 
+# Brackets suppress newlines the same way parentheses do,
+# so list literals can span several lines
+def t_LBRACE(t):
+    r"\["
+    t.lexer.paren_count += 1
+    return t
+
+
+def t_RBRACE(t):
+    r"\]"
+    t.lexer.paren_count -= 1
+    return t
+
+
 # Misc
 def t_WS(t):
-    r"[ ]+"
+    r"[ \t]+"
     if t.lexer.at_line_start and t.lexer.paren_count == 0:
         return t
 
 
 def t_newline(t):
     r"\n+"
-    t.lineno += len(t.value)
+    t.lexer.lineno += len(t.value)
     t.type = "NEWLINE"
     if t.lexer.paren_count == 0:
         return t
 
 
+# Block comments may nest, which a regex can't express, so the
+# closing delimiter is found by scanning the input by hand
+def t_block_comment(t):
+    r"/\*"
+    data, pos, depth = t.lexer.lexdata, t.lexer.pos, 1
+
+    while depth > 0 and pos < len(data):
+        if data.startswith("/*", pos):
+            depth += 1
+            pos += 2
+        elif data.startswith("*/", pos):
+            depth -= 1
+            pos += 2
+        else:
+            pos += 1
+
+    if depth > 0:
+        col = column(data, t.pos, t.lexer.tab_width)
+        print(f"Unterminated block comment starting at line {t.lineno}, column {col}")
+
+    # A comment that starts a line produces no token, so the line is
+    # still at its start afterwards; swallow the whitespace after it or
+    # t_WS would report it as a second indentation
+    if t.lexer.at_line_start:
+        while pos < len(data) and data[pos] in " \t":
+            pos += 1
+
+    t.lexer.lineno += data.count("\n", t.lexer.pos, pos)
+    t.lexer.pos = pos
+
+
 def t_error(t):
-    print(f"Illegal Character: '{t.value[0]}'")
+    col = column(t.lexer.lexdata, t.pos, t.lexer.tab_width)
+    print(f"Illegal Character: '{t.value[0]}' at line {t.lineno}, column {col}")
     t.lexer.skip(1)
 
 
@@ -1,16 +1,30 @@
 from ply import lex
+from core.helper_types import column
 
 
 class QuarkLexer:
-    def __init__(self, ply_lexer):
+    def __init__(self, ply_lexer, tab_width=4):
         self.lexer = ply_lexer
+        self.lexer.tab_width = self.tab_width = tab_width
         self.token_stream = None
 
+    def _column(self, pos):
+        return column(self.lexer.lexdata, pos, self.tab_width)
+
     def _new_token(self, type, lineno, pos):
         tok = lex.Token()
         tok.type, tok.value, tok.lineno, tok.pos = type, None, lineno, pos
+        tok.col = self._column(pos)
         return tok
 
+    def _check_mixed(self, depth, level, token):
+        # Two indents where neither extends the other differ in how they
+        # mix tabs and spaces, and there's no tab width to settle it
+        if not (depth.startswith(level) or level.startswith(depth)):
+            raise IndentationError(
+                f"inconsistent use of tabs and spaces in indentation at line {token.lineno}, column {token.col}"
+            )
+
     def _track_tokens_filter(self, tokens):
         NO_INDENT, MAY_INDENT, MUST_INDENT = 0, 1, 2
         self.lexer.at_line_start = at_line_start = True
@@ -18,6 +32,7 @@ class QuarkLexer:
         saw_colon = False
         for token in tokens:
             token.at_line_start = at_line_start
+            token.col = self._column(token.pos)
 
             if token.type == "COLON":
                 at_line_start = False
@@ -48,63 +63,99 @@ class QuarkLexer:
             self.lexer.at_line_start = at_line_start
 
     def _indentation_filter(self, tokens):
-        # A stack of indentation levels; will never pop item 0
-        levels = [0]
+        # A stack of indentation levels, kept as the leading whitespace
+        # itself; will never pop item 0
+        levels = [""]
         token = None
-        depth = 0
+        depth = ""
         prev_was_ws = False
+        # A NEWLINE is held back until the next real token is seen, so
+        # that a line starting with PIPE continues the previous one
+        pending_newline = None
         for token in tokens:
             # WS only occurs at the start of the line
             # There may be WS followed by NEWLINE so
             # only track the depth here.  Don't indent/dedent
             # until there's something real.
             if token.type == "WS":
-                assert depth == 0
-                depth = len(token.value)
+                assert depth == ""
+                # Compared as text, so the meaning of a block never
+                # depends on tab_width, which only affects columns
+                depth = token.value
                 prev_was_ws = True
                 # WS tokens are never passed to the parser
                 continue
 
             if token.type == "NEWLINE":
-                depth = 0
+                depth = ""
                 if prev_was_ws or token.at_line_start:
                     # ignore blank lines
                     continue
-                # pass the other cases on through
-                yield token
+                # hold the other cases until the next real token
+                pending_newline = token
                 continue
 
             # then it must be a real token (not WS, not NEWLINE)
             # which can affect the indentation level
 
             prev_was_ws = False
+            if pending_newline:
+                if token.type == "PIPE":
+                    # Pipe chain continuation; blank and comment-only
+                    # lines in between were already dropped above. A
+                    # dedented '|' would silently join the enclosing
+                    # block's last line, so it must stay at or past the
+                    # current level.
+                    self._check_mixed(depth, levels[-1], token)
+                    if not depth.startswith(levels[-1]):
+                        raise IndentationError(
+                            f"pipe continuation dedents out of its block at line {token.lineno}, column {token.col}"
+                        )
+                    pending_newline = None
+                    yield token
+                    continue
+
+                yield pending_newline
+                pending_newline = None
+
             if token.must_indent:
-                # The current depth must be larger than the previous level
-                if not (depth > levels[-1]):
-                    raise IndentationError("expected an indented block")
+                # The current depth must extend the previous level
+                self._check_mixed(depth, levels[-1], token)
+                if not (len(depth) > len(levels[-1])):
+                    raise IndentationError(
+                        f"expected an indented block at line {token.lineno}, column {token.col}"
+                    )
 
                 levels.append(depth)
                 yield self._new_token("INDENT", token.lineno, token.pos)
 
             elif token.at_line_start:
                 # Must be on the same level or one of the previous levels
+                self._check_mixed(depth, levels[-1], token)
                 if depth == levels[-1]:
                     # At the same level
                     pass
-                elif depth > levels[-1]:
-                    raise IndentationError("indentation increase but not in new block")
+                elif len(depth) > len(levels[-1]):
+                    raise IndentationError(
+                        f"indentation increase but not in new block at line {token.lineno}, column {token.col}"
+                    )
                 else:
                     # Back up; but only if it matches a previous level
                     try:
                         i = levels.index(depth)
                     except ValueError:
-                        raise IndentationError("inconsistent indentation")
+                        raise IndentationError(
+                            f"inconsistent indentation at line {token.lineno}, column {token.col}"
+                        )
                     for _ in range(i + 1, len(levels)):
                         yield self._new_token("DEDENT", token.lineno, token.pos)
                         levels.pop()
 
             yield token
 
+        if pending_newline:
+            yield pending_newline
+
         # Must dedent any remaining levels
         if len(levels) > 1:
             assert token is not None
@@ -126,6 +177,13 @@ class QuarkLexer:
     def input(self, source, add_endmarker=True):
         self.lexer.paren_count = 0
         self.lexer.input(source)
+
+        # Skip a '#!' interpreter line but keep its newline so that
+        # line numbers and positions stay the same
+        if source.startswith("#!"):
+            end = source.find("\n")
+            self.lexer.skip(end if end >= 0 else len(source))
+
         self.token_stream = self._indent_filter(add_endmarker)
 
     def token(self):
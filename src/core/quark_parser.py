@@ -3,21 +3,37 @@ from .helper_types import NodeType, TreeNode
 
 
 class QuarkParser:
-    def __init__(self, token_stream):
+    def __init__(self, token_stream, verbose=False):
         self.tree = None
+        self.verbose = verbose
+        self.fn_depth = 0
+        self.loop_depth = 0
         self.tokens = list(token_stream)
         self.expr_parser = ExprParser(self)
         self.prev, self.cur = None, self.tokens[0]
 
     # Util functions
+    def log(self, *args):
+        if self.verbose:
+            print(*args)
+
     def peek(self, index=1):
         return self.tokens[index] if index < len(self.tokens) else None
 
     def consume(self):
-        self.prev = self.tokens.pop(0)
+        # EOF stays as the current token, so a parser that runs off the
+        # end reports it with a position instead of an IndexError
+        self.prev = self.tokens.pop(0) if len(self.tokens) > 1 else self.tokens[0]
         self.cur = self.tokens[0]
         return self.prev
 
+    def end_statement(self, keyword):
+        # Statements led by a keyword take nothing after their operand
+        if self.cur.type not in ["NEWLINE", "DEDENT", "ELIF", "ELSE", "EOF"]:
+            raise Exception(
+                f"Unexpected {self.cur.type} after '{keyword}' at line {self.cur.lineno}, column {self.cur.col}."
+            )
+
     def is_term(self, token):
         return token.type in ["ID", "INT", "FLOAT", "STR"]
 
@@ -25,29 +41,57 @@ class QuarkParser:
         if self.cur.type == type:
             return self.consume()
         else:
-            raise Exception(f"Expected {type} but got {self.cur.type}.")
+            raise Exception(
+                f"Expected {type} but got {self.cur.type} at line {self.cur.lineno}, column {self.cur.col}."
+            )
 
     # Parsing functions
     def block(self):
-        print(f"Block: {self.cur}")
+        self.log(f"Block: {self.cur}")
         node = TreeNode(NodeType.Block)
 
         if self.cur.type == "NEWLINE" and self.peek().type == "INDENT":
-            pass
+            self.consume()
+            self.consume()
+            self.statements(node, "DEDENT")
+            self.expect("DEDENT")
         else:
-            while self.cur.type != "NEWLINE":
+            # An inline block ends with its line, or before an else/elif
+            # on the same line as in `if c: a else: b`
+            while self.cur.type not in ["NEWLINE", "ELIF", "ELSE", "EOF"]:
                 node.children.append(self.statement())
-            self.expect("NEWLINE")
+
+                # A nested compound statement already consumed the line end
+                if self.prev.type in ["NEWLINE", "DEDENT"]:
+                    return node
+
+            if self.cur.type == "NEWLINE":
+                self.consume()
+
+        return node
+
+    def statements(self, node, end):
+        while self.cur.type != end:
+            node.children.append(self.statement())
+
+            if self.cur.type == "NEWLINE":
+                self.consume()
 
         return node
 
     def statement(self):
-        print(f"Statement: {self.cur}")
+        self.log(f"Statement: {self.cur}")
         node = None
 
         if self.cur.type == "IF":
             node = self.ifelse()
-        elif "FN" in [self.cur.type, self.peek(2).type]:
+        elif self.cur.type == "WHILE":
+            node = self.loop()
+        elif self.cur.type == "RETURN":
+            node = self.ret()
+        elif self.cur.type in ["BREAK", "CONTINUE"]:
+            node = self.jump()
+        elif "FN" in [self.cur.type, getattr(self.peek(2), "type", None)]:
             node = self.function()
         elif self.cur.type == "AT":
             self.consume()
@@ -58,11 +102,11 @@ class QuarkParser:
         return node
 
     def expression(self):
-        print(f"Expression: {self.cur}")
+        self.log(f"Expression: {self.cur}")
         return self.expr_parser.parse()
 
     def function(self):
-        print(f"Function: {self.cur}")
+        self.log(f"Function: {self.cur}")
         node = None
 
         if self.cur.type == "FN":
@@ -71,19 +115,67 @@ class QuarkParser:
                 [TreeNode(NodeType.Identifier, self.expect("ID")), self.arguments()]
             )
             self.expect("COLON")
-            node.children.append(self.block())
+            node.children.append(self.function_body())
         elif self.peek(2).type == "FN":
             id = TreeNode(NodeType.Identifier, self.expect("ID"))
             self.expect("EQUALS")
             node = TreeNode(NodeType.Function, self.consume())
             node.children.extend([id, self.arguments()])
             self.expect("COLON")
-            node.children.append(self.block())
+            node.children.append(self.function_body())
+
+        return node
 
+    def function_body(self):
+        # Tracked so that 'return' can be rejected outside of functions,
+        # and 'break' in a function can't target a loop around it
+        self.fn_depth += 1
+        loop_depth, self.loop_depth = self.loop_depth, 0
+        node = self.block()
+        self.fn_depth -= 1
+        self.loop_depth = loop_depth
+        return node
+
+    def loop(self):
+        self.log(f"Loop: {self.cur}")
+        node = TreeNode(NodeType.Loop, self.consume())
+        node.children.append(self.expression())
+        self.expect("COLON")
+
+        self.loop_depth += 1
+        node.children.append(self.block())
+        self.loop_depth -= 1
+
+        return node
+
+    def jump(self):
+        self.log(f"Jump: {self.cur}")
+        if self.loop_depth == 0:
+            raise Exception(
+                f"'{self.cur.value}' outside of a loop at line {self.cur.lineno}, column {self.cur.col}."
+            )
+
+        type = NodeType.Break if self.cur.type == "BREAK" else NodeType.Continue
+        node = TreeNode(type, self.consume())
+        self.end_statement(node.tok.value)
+        return node
+
+    def ret(self):
+        self.log(f"Return: {self.cur}")
+        if self.fn_depth == 0:
+            raise Exception(
+                f"'return' outside of a function at line {self.cur.lineno}, column {self.cur.col}."
+            )
+
+        node = TreeNode(NodeType.Return, self.consume())
+        if self.cur.type not in ["NEWLINE", "DEDENT", "ELIF", "ELSE", "EOF"]:
+            node.children.append(self.expression())
+
+        self.end_statement("return")
         return node
 
     def function_call(self):
-        print(f"Function Call: {self.cur}")
+        self.log(f"Function Call: {self.cur}")
         node = TreeNode(NodeType.FunctionCall)
         node.children.extend(
             [TreeNode(NodeType.Identifier, self.expect("ID")), self.arguments()]
@@ -91,20 +183,38 @@ class QuarkParser:
         return node
 
     def arguments(self):
-        print(f"Arguments: {self.cur}")
+        self.log(f"Arguments: {self.cur}")
         node = TreeNode(NodeType.Arguments)
 
-        while self.cur.type not in ["COLON", "NEWLINE"]:
+        # Arguments also end before an inline else/elif and at the end
+        # of a block or file, as in `if c: @f 1 else: @f 2`
+        while self.cur.type not in [
+            "COLON", "NEWLINE", "RPAR", "RBRACE", "ELSE", "ELIF", "DEDENT", "EOF"
+        ]:
             node.children.append(self.expression())
 
             if self.cur.type == "COMMA":
                 self.consume()
 
-        print(node)
+        self.log(node)
         return node
 
     def ifelse(self):
-        pass
+        self.log(f"If: {self.cur}")
+        node = TreeNode(NodeType.Condition, self.consume())
+        node.children.append(self.expression())
+        self.expect("COLON")
+        node.children.append(self.block())
+
+        # An elif chain nests as the else branch of the previous condition
+        if self.cur.type == "ELIF":
+            node.children.append(self.ifelse())
+        elif self.cur.type == "ELSE":
+            self.consume()
+            self.expect("COLON")
+            node.children.append(self.block())
+
+        return node
 
     def term(self):
         return TreeNode(
@@ -114,4 +224,4 @@ class QuarkParser:
 
     def parse(self):
         self.tree = TreeNode(NodeType.CompilationUnit)
-        self.tree.children.append(self.block())
+        self.tree.children.append(self.statements(TreeNode(NodeType.Block), "EOF"))
@@ -1,4 +1,4 @@
-import sys
+import argparse
 import ply.lex as lex
 from core.lex_grammar import *
 from core.helper_types import *
@@ -9,9 +9,14 @@ import pytreetonative as cg
 lexer = QuarkLexer(lex.lex())
 
 if __name__ == "__main__":
-    with open(sys.argv[1], "r") as inputf:
+    argparser = argparse.ArgumentParser(description="Parse a Quark file and hand the tree to the native backend.")
+    argparser.add_argument("file", help="path to the .qrk source file")
+    argparser.add_argument("-v", "--verbose", action="store_true", help="trace parser progress")
+    args = argparser.parse_args()
+
+    with open(args.file, "r") as inputf:
         lexer.input(inputf.read())
-        parser = QuarkParser(lexer.token_stream)
+        parser = QuarkParser(lexer.token_stream, verbose=args.verbose)
         parser.parse()
 
         if parser.tree:
@@ -0,0 +1,28 @@
+import argparse
+import ply.lex as lex
+from core.lex_grammar import *
+from core.quark_lexer import QuarkLexer
+from core.quark_parser import QuarkParser
+from core.interpreter import QuarkInterpreter
+
+# Lexer
+lexer = QuarkLexer(lex.lex())
+
+if __name__ == "__main__":
+    argparser = argparse.ArgumentParser(description="Evaluate a Quark file with the tree-walking interpreter.")
+    argparser.add_argument("file", help="path to the .qrk source file")
+    argparser.add_argument("args", nargs=argparse.REMAINDER, help="arguments passed to the program")
+    argparser.add_argument("-v", "--verbose", action="store_true", help="trace parser progress")
+    args = argparser.parse_args()
+
+    with open(args.file, "r") as inputf:
+        lexer.input(inputf.read())
+        parser = QuarkParser(lexer.token_stream, verbose=args.verbose)
+        parser.parse()
+
+        if parser.tree:
+            result = QuarkInterpreter(args.args).evaluate(parser.tree)
+            if result is not None:
+                print(result)
+        else:
+            print("Parser tree is Null.")
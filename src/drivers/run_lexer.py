@@ -1,4 +1,4 @@
-import sys
+import argparse
 import ply.lex as lex
 from core.lex_grammar import *
 from core.quark_lexer import QuarkLexer
@@ -8,7 +8,11 @@ lexer = QuarkLexer(lex.lex())
 
 
 if __name__ == "__main__":
-    with open(sys.argv[1], "r") as inputf:
+    argparser = argparse.ArgumentParser(description="Print the token stream of a Quark file.")
+    argparser.add_argument("file", help="path to the .qrk source file")
+    args = argparser.parse_args()
+
+    with open(args.file, "r") as inputf:
         lexer.input(inputf.read())
 
         for i, tok in enumerate(lexer.token_stream):
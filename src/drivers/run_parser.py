@@ -1,4 +1,4 @@
-import sys
+import argparse
 import ply.lex as lex
 from utils import treeviz
 from core.lex_grammar import *
@@ -9,14 +9,20 @@ from core.quark_parser import QuarkParser
 lexer = QuarkLexer(lex.lex())
 
 if __name__ == "__main__":
-    with open(sys.argv[1], "r") as inputf:
+    argparser = argparse.ArgumentParser(description="Parse a Quark file and render its tree as a dot graph.")
+    argparser.add_argument("file", help="path to the .qrk source file")
+    argparser.add_argument("-o", "--output", default="treeviz.dot", help="path of the generated dot file")
+    argparser.add_argument("-v", "--verbose", action="store_true", help="trace parser progress")
+    args = argparser.parse_args()
+
+    with open(args.file, "r") as inputf:
         lexer.input(inputf.read())
-        parser = QuarkParser(lexer.token_stream)
+        parser = QuarkParser(lexer.token_stream, verbose=args.verbose)
 
         parser.parse()
         viz = treeviz.TreeViz()
         if parser.tree:
             viz.generate(parser.tree)
-            viz.save()
+            viz.save(args.output)
         else:
             print("Parser tree is Null.")
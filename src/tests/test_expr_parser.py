@@ -0,0 +1,110 @@
+import unittest
+from core.expr_parser import ExprParser
+from core.helper_types import Precedence
+from tests.util import parse, run, sexpr
+
+
+def expr(source):
+    return sexpr(parse(source + "\n").children[0].children[0])
+
+
+class PrecedenceTest(unittest.TestCase):
+    def assertGroups(self, cases):
+        for source, expected in cases:
+            with self.subTest(source=source):
+                self.assertEqual(expr(source), expected)
+
+    def test_arithmetic(self):
+        self.assertGroups(
+            [
+                ("1 + 2 * 3", "(+ 1 (* 2 3))"),
+                ("1 * 2 + 3", "(+ (* 1 2) 3)"),
+                ("1 - 2 - 3", "(- (- 1 2) 3)"),
+                ("8 / 4 / 2", "(/ (/ 8 4) 2)"),
+                ("(1 + 2) * 3", "(* (+ 1 2) 3)"),
+                ("-1 + 2", "(+ (- 1) 2)"),
+                ("-a * b", "(* (- a) b)"),
+            ]
+        )
+
+    def test_comparison_chains(self):
+        self.assertGroups(
+            [
+                ("a < b < c", "(< (< a b) c)"),
+                ("a <= b >= c", "(>= (<= a b) c)"),
+                ("a == b != c", "(!= (== a b) c)"),
+                ("a < b == c > d", "(== (< a b) (> c d))"),
+                ("a + 1 < b * 2", "(< (+ a 1) (* b 2))"),
+            ]
+        )
+
+    def test_assignment(self):
+        self.assertGroups(
+            [
+                ("x = 1 + 2", "(= x (+ 1 2))"),
+                ("x = a == b", "(= x (== a b))"),
+                ("x = 1..10", "(= x (.. 1 10))"),
+            ]
+        )
+
+    def test_ranges(self):
+        self.assertGroups(
+            [
+                ("1..n + 1", "(.. 1 (+ n 1))"),
+                ("a * 2..=b", "(..= (* a 2) b)"),
+                ("1..3 == r", "(== (.. 1 3) r)"),
+            ]
+        )
+
+    def test_pipes(self):
+        self.assertGroups(
+            [
+                ("x = y | f", "(= x (| y f))"),
+                ("a | f == b", "(== (| a f) b)"),
+                ("a | f | g", "(| (| a f) g)"),
+                ("a + 1 | f", "(| (+ a 1) f)"),
+                ("1..10 | f", "(| (.. 1 10) f)"),
+                ("a | f < b | g", "(< (| a f) (| b g))"),
+                ("x = y | (@f 1)", "(= x (| y (FunctionCall f (Arguments 1))))"),
+                ("a | (@f 1) == b", "(== (| a (FunctionCall f (Arguments 1))) b)"),
+            ]
+        )
+
+    def test_pipe_target_must_be_callable(self):
+        for source in ["2 | f + 1", "2 | 3", "2 | (a == b)"]:
+            with self.subTest(source=source):
+                with self.assertRaisesRegex(Exception, "Expected a function name or @ call after '\\|' at line 1, column 3"):
+                    parse(source + "\n")
+
+    def test_missing_operand_at_end_of_input(self):
+        cases = [("1 +", 3), ("x =", 3), ("1 +\n", 4)]
+        for source, col in cases:
+            with self.subTest(source=source):
+                with self.assertRaisesRegex(Exception, f"Expected expression at line 1, column {col}\\."):
+                    parse(source)
+
+    def test_pipe_evaluation(self):
+        self.assertEqual(run("fn inc x: x + 1\n2 | inc | inc == 4\n"), True)
+        self.assertEqual(run("fn sub a, b: a - b\n10 | (@sub 3)\n"), 7)
+        self.assertEqual(run("fn sub a, b: a - b\n10 | @sub 3\n"), 7)
+
+
+class PrecedenceTableTest(unittest.TestCase):
+    def test_table_orders_levels(self):
+        table = ExprParser(None).precedence_table()
+        self.assertLess(table["EQUALS"], table["DEQ"])
+        self.assertLess(table["DEQ"], table["LT"])
+        self.assertLess(table["LT"], table["PIPE"])
+        self.assertLess(table["PIPE"], table["DOTDOT"])
+        self.assertLess(table["DOTDOT"], table["PLUS"])
+        self.assertLess(table["PLUS"], table["MULTIPLY"])
+
+    def test_table_lists_only_infix_operators(self):
+        table = ExprParser(None).precedence_table()
+        self.assertNotIn("INT", table)
+        self.assertNotIn("LPAR", table)
+        self.assertEqual(table["PIPE"], Precedence.Pipe)
+
+
+if __name__ == "__main__":
+    unittest.main()
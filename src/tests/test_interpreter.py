@@ -0,0 +1,115 @@
+import unittest
+from tests.util import run
+
+
+class InterpreterTest(unittest.TestCase):
+    def test_arithmetic(self):
+        self.assertEqual(run("x = 2\nx * 3 + 1\n"), 7)
+
+    def test_integer_division_truncates_toward_zero(self):
+        self.assertEqual(run("7 / 2\n"), 3)
+        self.assertEqual(run("(0 - 7) / 2\n"), -3)
+        self.assertEqual(run("7 / (0 - 2)\n"), -3)
+        self.assertEqual(run("(0 - 7) / (0 - 2)\n"), 3)
+        self.assertEqual(run("(0 - 6) / 3\n"), -2)
+        self.assertEqual(run("7.0 / 2\n"), 3.5)
+
+    def test_function_call(self):
+        self.assertEqual(run("fn add x, y: x + y\n@add 2, 5\n"), 7)
+
+    def test_callee_sees_globals(self):
+        self.assertEqual(run("k = 10\nfn f x: x + k\n@f 1\n"), 11)
+
+    def test_callee_does_not_see_caller_frame(self):
+        source = "fn g:\n    y\nfn f y:\n    @g\n@f 5\n"
+        with self.assertRaisesRegex(NameError, "Undefined identifier 'y'"):
+            run(source)
+
+    def test_nested_function_sees_enclosing_locals(self):
+        self.assertEqual(run("fn f x:\n    fn g: x\n    @g\n@f 1\n"), 1)
+
+    def test_nested_function_is_local_to_its_definer(self):
+        source = "fn f x:\n    fn g: x\n    @g\n@f 1\n@g\n"
+        with self.assertRaisesRegex(NameError, "Undefined function 'g'"):
+            run(source)
+
+    def test_closure_keeps_defining_frame(self):
+        source = "fn outer x:\n    fn inner y: x + y\n    @inner 10\nk = 5\n@outer 1\n"
+        self.assertEqual(run(source), 11)
+
+    def test_undefined_identifier_suggests_close_name(self):
+        with self.assertRaisesRegex(NameError, "Undefined identifier 'cout'. Did you mean 'count'\\?"):
+            run("count = 1\ncout + 1\n")
+
+    def test_undefined_function_suggests_only_functions(self):
+        source = "totl = 1\nfn total x: x\n@totl 1\n"
+        with self.assertRaisesRegex(NameError, "Undefined function 'totl'. Did you mean 'total'\\?"):
+            run("fn total x: x\n@totl 1\n")
+        with self.assertRaisesRegex(TypeError, "'totl' is not a function"):
+            run(source)
+
+    def test_no_suggestion_for_distant_names(self):
+        with self.assertRaisesRegex(NameError, r"Undefined identifier 'zebra'\.$"):
+            run("count = 1\nzebra\n")
+
+    def test_recursion(self):
+        source = "fn fact n:\n    if n < 2: return 1\n    n * (@fact n - 1)\n@fact 5\n"
+        self.assertEqual(run(source), 120)
+
+    def test_calling_a_non_function(self):
+        with self.assertRaisesRegex(TypeError, "'x' is not a function"):
+            run("x = 1\n@x\n")
+
+    def test_assignment_in_function_is_local(self):
+        source = "y = 1\nfn f x:\n    y = x\n    y\n@f 5\ny\n"
+        self.assertEqual(run(source), 1)
+
+    def test_ranges(self):
+        self.assertEqual(run("1..4\n"), [1, 2, 3])
+        self.assertEqual(run("1..=4\n"), [1, 2, 3, 4])
+
+    def test_range_rejects_non_integer_bounds(self):
+        with self.assertRaisesRegex(TypeError, "Range bounds must be integers at line 1, column 4"):
+            run("1.5..3\n")
+
+    def test_while_loop(self):
+        self.assertEqual(run("i = 0\nwhile i < 5: i = i + 1\ni\n"), 5)
+
+    def test_break_inside_if_exits_the_loop(self):
+        source = "i = 0\nwhile 1:\n    i = i + 1\n    if i == 4: break\ni\n"
+        self.assertEqual(run(source), 4)
+
+    def test_continue_skips_rest_of_body(self):
+        source = (
+            "i = 0\nn = 0\n"
+            "while i < 5:\n"
+            "    i = i + 1\n"
+            "    if i == 3: continue\n"
+            "    n = n + i\n"
+            "n\n"
+        )
+        self.assertEqual(run(source), 12)
+
+    def test_break_exits_only_the_innermost_loop(self):
+        source = (
+            "i = 0\nn = 0\n"
+            "while i < 3:\n"
+            "    i = i + 1\n"
+            "    while 1:\n"
+            "        n = n + 1\n"
+            "        break\n"
+            "n\n"
+        )
+        self.assertEqual(run(source), 3)
+
+    def test_return_exits_early(self):
+        source = "fn clamp x:\n    if x > 10: return 10\n    x\n[(@clamp 50), (@clamp 4)]\n"
+        self.assertEqual(run(source), [10, 4])
+
+    def test_return_outside_function_is_rejected(self):
+        with self.assertRaisesRegex(Exception, "'return' outside of a function at line 1"):
+            run("return 1\n")
+
+
+if __name__ == "__main__":
+    unittest.main()
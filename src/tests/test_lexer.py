@@ -0,0 +1,153 @@
+import io
+import unittest
+from contextlib import redirect_stdout
+from core.helper_types import column
+from tests.util import tokens, parse, run, sexpr
+
+
+def types(source):
+    return [tok.type for tok in tokens(source)]
+
+
+class BlockCommentTest(unittest.TestCase):
+    def test_inline_comment(self):
+        self.assertEqual(types("x = 1 /* c */ + 2\n"), ["ID", "EQUALS", "INT", "PLUS", "INT", "NEWLINE", "EOF"])
+
+    def test_nested_comment(self):
+        self.assertEqual(types("/* a /* b */ c */ x\n"), ["ID", "NEWLINE", "EOF"])
+
+    def test_comment_advances_line_numbers(self):
+        toks = tokens("/* a\n   b\n*/\nx\n")
+        self.assertEqual((toks[0].type, toks[0].lineno), ("ID", 4))
+
+    def test_comment_starting_indented_line(self):
+        self.assertEqual(run("fn f:\n    /* x */ 1\n@f\n"), 1)
+
+    def test_multiline_comment_before_code_at_top_level(self):
+        self.assertEqual(run("x = 1\n/* a\n b */ x\n"), 1)
+
+    def test_comment_after_indentation_keeps_block_depth(self):
+        source = "fn f:\n    y = 2\n    /* skip\n       this */   y * 3\n@f\n"
+        self.assertEqual(run(source), 6)
+
+
+class EscapeTest(unittest.TestCase):
+    def lex(self, source):
+        # Escape warnings are printed, so capture them alongside the tokens
+        with redirect_stdout(io.StringIO()) as out:
+            toks = tokens(source)
+        return toks, out.getvalue()
+
+    def test_known_escapes(self):
+        toks, out = self.lex('"a\\nb\\tc\\x41\\u{1F600}\\u{e9}"\n')
+        self.assertEqual(toks[0].value, "a\nb\tcA\U0001F600\u00e9")
+        self.assertEqual(out, "")
+
+    def test_escaped_quotes_stay_inside_the_string(self):
+        toks, _ = self.lex('"say \\"hi\\"" + "x"\n')
+        self.assertEqual([(t.type, t.value) for t in toks[:3]], [("STR", 'say "hi"'), ("PLUS", "+"), ("STR", "x")])
+
+        # An escaped backslash doesn't escape the closing quote
+        toks, _ = self.lex('"a\\\\" + "b"\n')
+        self.assertEqual([t.value for t in toks[:3]], ["a\\", "+", "b"])
+
+    def test_unknown_escape_is_kept_and_reported(self):
+        toks, out = self.lex('x = 1\ns = "a\\qb"\n')
+        self.assertEqual(toks[6].value, "a\\qb")
+        self.assertEqual(out, "Unknown escape sequence '\\q' at line 2, column 7\n")
+
+    def test_out_of_range_code_point_is_reported(self):
+        toks, out = self.lex('"a\\u{110000}b"\n')
+        self.assertEqual(toks[0].value, "ab")
+        self.assertEqual(out, "Invalid code point '\\u{110000}' at line 1, column 3\n")
+
+
+class RawStringTest(unittest.TestCase):
+    def test_backslashes_and_quotes_stay_verbatim(self):
+        tok = tokens('`C:\\path\\n "q" \'s\' \\x41`\n')[0]
+        self.assertEqual((tok.type, tok.value), ("STR", 'C:\\path\\n "q" \'s\' \\x41'))
+
+    def test_multiline_raw_string_advances_lineno(self):
+        toks = tokens("s = `one\ntwo\nthree`\nx = 1\n")
+        self.assertEqual(toks[2].value, "one\ntwo\nthree")
+        self.assertEqual((toks[2].lineno, toks[4].type, toks[4].lineno), (1, "ID", 4))
+
+    def test_raw_string_evaluates_to_contents(self):
+        self.assertEqual(run("`a\\b`\n"), "a\\b")
+
+
+class ShebangTest(unittest.TestCase):
+    def test_shebang_line_is_skipped(self):
+        toks = tokens("#!/usr/bin/env quark\nx = 1\n")
+        self.assertEqual([tok.type for tok in toks], ["ID", "EQUALS", "INT", "NEWLINE", "EOF"])
+        self.assertEqual((toks[0].lineno, toks[0].pos, toks[0].col), (2, 21, 1))
+
+    def test_shebang_only_file(self):
+        self.assertEqual(types("#!/usr/bin/env quark"), ["EOF"])
+        self.assertEqual(types("#!/usr/bin/env quark\n"), ["EOF"])
+        self.assertIsNone(run("#!/usr/bin/env quark"))
+
+    def test_args_are_bound(self):
+        self.assertEqual(run("#!/usr/bin/env quark\nargs\n", ["a", "--b"]), ["a", "--b"])
+        self.assertEqual(run("args\n"), [])
+
+
+class ColumnTest(unittest.TestCase):
+    def test_tabs_advance_to_the_next_stop(self):
+        self.assertEqual(column("\tx", 1), 5)
+        self.assertEqual(column("ab\tx", 3), 5)
+        self.assertEqual(column("abcd\tx", 5), 9)
+        self.assertEqual(column("\tx", 1, tab_width=8), 9)
+        self.assertEqual(column("a\n\t\tx", 4, tab_width=2), 5)
+
+    def test_multibyte_characters_take_one_column(self):
+        self.assertEqual(column("é = 1", 4), 5)
+        self.assertEqual(column("s = \"日本\" + x", 11), 12)
+        self.assertEqual(column("😀\tx", 2), 5)
+
+    def test_token_columns(self):
+        toks = tokens("if a:\n\tx = \"é\" + y\n")
+        self.assertEqual([(t.type, t.col) for t in toks[5:10]], [("ID", 5), ("EQUALS", 7), ("STR", 9), ("PLUS", 13), ("ID", 15)])
+        self.assertEqual(tokens("if a:\n\tx\n", tab_width=8)[5].col, 9)
+
+    def test_illegal_character_reports_column(self):
+        with redirect_stdout(io.StringIO()) as out:
+            tokens("if a:\n\tx = \"é\" $\n")
+        self.assertEqual(out.getvalue(), "Illegal Character: '$' at line 2, column 13\n")
+
+
+class IndentationTest(unittest.TestCase):
+    def test_tab_indented_blocks(self):
+        source = "fn f x:\n\tif x:\n\t\t1\n\telse: 2\n@f 0\n"
+        self.assertEqual(types(source).count("INDENT"), 2)
+        self.assertEqual(run(source), 2)
+
+    def test_meaning_does_not_depend_on_tab_width(self):
+        # One tab is one level whatever its width, even against 8 spaces
+        source = "if a:\n\tif b:\n\t\tx\n\ty\nz\n"
+        shapes = {tab_width: [sexpr(node) for node in parse(source, tab_width).children[0].children] for tab_width in [2, 4, 8]}
+        self.assertEqual(shapes[4], ["(if a (Block (if b (Block x)) y))", "z"])
+        self.assertEqual(shapes[2], shapes[4])
+        self.assertEqual(shapes[8], shapes[4])
+
+    def test_mixed_tabs_and_spaces_are_rejected(self):
+        cases = [
+            ("if a:\n    x\n\ty\n", 3, 5),
+            ("if a:\n\tx\n        y\n", 3, 9),
+            ("if a:\n\tif b:\n\t    x\n\t\ty\n", 4, 9),
+        ]
+        for source, line, col in cases:
+            with self.subTest(source=source):
+                with self.assertRaisesRegex(
+                    IndentationError,
+                    f"inconsistent use of tabs and spaces in indentation at line {line}, column {col}",
+                ):
+                    tokens(source)
+
+    def test_spaces_only_dedent_must_match_a_level(self):
+        with self.assertRaisesRegex(IndentationError, "inconsistent indentation at line 4, column 5"):
+            tokens("if a:\n  if b:\n      x\n    y\n")
+
+
+if __name__ == "__main__":
+    unittest.main()
@@ -0,0 +1,194 @@
+import unittest
+from tests.util import parse, run, sexpr
+
+
+def statements(source):
+    return [sexpr(node) for node in parse(source).children[0].children]
+
+
+class MultiLineTest(unittest.TestCase):
+    def test_list_literal(self):
+        self.assertEqual(statements("x = [1, 2 + 3]\n"), ["(= x ([ 1 (+ 2 3)))"])
+        self.assertEqual(statements("x = []\n"), ["(= x [)"])
+
+    def test_list_literal_across_lines(self):
+        source = "x = [\n    1,  // one\n    /* two */ 2,\n\n    3,\n]\ny = 4\n"
+        self.assertEqual(statements(source), ["(= x ([ 1 2 3))", "(= y 4)"])
+        self.assertEqual(run(source.replace("y = 4\n", "x\n")), [1, 2, 3])
+
+    def test_nested_lists(self):
+        self.assertEqual(run("[[1,\n 2], [3]]\n"), [[1, 2], [3]])
+
+    def test_call_arguments_across_lines(self):
+        source = "fn add x, y: x + y\nr = (@add\n    1,   // first\n    // between\n    2,\n) * 3\nr\n"
+        self.assertEqual(run(source), 9)
+
+    def test_call_inside_list(self):
+        self.assertEqual(run("fn add x, y: x + y\n[@add 1, 2]\n"), [3])
+
+
+class PipelineTest(unittest.TestCase):
+    FUNCTIONS = "fn inc x: x + 1\nfn double x: x * 2\nfn sub a, b: a - b\n"
+
+    def test_pipeline_across_lines(self):
+        source = "x = 1\n    | inc\n    | double\n    | (@sub 3)\n"
+        self.assertEqual(
+            statements(source),
+            ["(= x (| (| (| 1 inc) double) (FunctionCall sub (Arguments 3))))"],
+        )
+
+    def test_pipeline_with_comments_and_blank_lines(self):
+        source = (
+            self.FUNCTIONS
+            + "x = 5\n"
+            + "    // first bump\n"
+            + "    | inc\n"
+            + "\n"
+            + "    /* then\n       double */\n"
+            + "    | double\n"
+            + "    // and subtract\n"
+            + "    | @sub 2\n"
+            + "x\n"
+        )
+        self.assertEqual(run(source), 10)
+
+    def test_pipeline_inside_block(self):
+        source = self.FUNCTIONS + "fn f a:\n    a\n        | inc\n    | double\n@f 3\n"
+        self.assertEqual(run(source), 8)
+
+    def test_long_pipeline_followed_by_statement(self):
+        stages = "".join("  | inc\n" for _ in range(20))
+        self.assertEqual(run(self.FUNCTIONS + "x = 0\n" + stages + "x * 10\n"), 200)
+
+    def test_dedented_pipe_is_rejected(self):
+        source = "fn f a:\n    a\n| f\n@f 2\n"
+        with self.assertRaisesRegex(IndentationError, "pipe continuation dedents out of its block at line 3"):
+            parse(source)
+
+
+class IfElseTest(unittest.TestCase):
+    FUNCTIONS = "fn f x: x * 10\n"
+
+    def test_inline_if_else(self):
+        self.assertEqual(statements("if c: 1 else: 2\n"), ["(if c (Block 1) (Block 2))"])
+
+    def test_inline_branches_with_calls(self):
+        source = self.FUNCTIONS + "c = 0\nif c: @f 1 else: @f 2\n"
+        self.assertEqual(
+            statements(source)[-1],
+            "(if c (Block (FunctionCall f (Arguments 1))) (Block (FunctionCall f (Arguments 2))))",
+        )
+        self.assertEqual(run(source), 20)
+
+    def test_inline_elif_chain_with_calls(self):
+        source = self.FUNCTIONS + "c = 2\nif c == 1: @f 1 elif c == 2: @f 2 else: @f 3\n"
+        self.assertEqual(run(source), 20)
+
+    def test_call_on_last_line_without_newline(self):
+        self.assertEqual(run(self.FUNCTIONS + "@f 1"), 10)
+        self.assertEqual(run("x = 3\nx"), 3)
+
+    def test_elif_and_elseif_nest_as_else_branch(self):
+        source = "if a: 1\nelif b: 2\nelseif c: 3\nelse: 4\n"
+        self.assertEqual(
+            statements(source),
+            ["(if a (Block 1) (elif b (Block 2) (elseif c (Block 3) (Block 4))))"],
+        )
+
+    def test_indented_branches(self):
+        source = "if a:\n    x = 1\n    x\nelse:\n    2\n"
+        self.assertEqual(statements(source), ["(if a (Block (= x 1) x) (Block 2))"])
+
+    def test_mixed_inline_and_indented_branches(self):
+        source = "if a: 1\nelif b:\n    y = 2\n    y\nelse: 3\nz\n"
+        self.assertEqual(
+            statements(source),
+            ["(if a (Block 1) (elif b (Block (= y 2) y) (Block 3)))", "z"],
+        )
+
+    def test_indented_if_with_inline_else(self):
+        source = "if a:\n    1\nelse: 2\n"
+        self.assertEqual(statements(source), ["(if a (Block 1) (Block 2))"])
+
+    def test_nested_conditions_in_function(self):
+        source = (
+            "fn sign x:\n"
+            "    if x < 0: return 0 - 1\n"
+            "    elseif x == 0:\n"
+            "        0\n"
+            "    else:\n"
+            "        if x > 100: 2 else: 1\n"
+            "[(@sign 0 - 5), (@sign 0), (@sign 7), (@sign 500)]\n"
+        )
+        self.assertEqual(run(source), [-1, 0, 1, 2])
+
+
+class LoopTest(unittest.TestCase):
+    def test_while_with_break_and_continue(self):
+        source = "while i < 3:\n    if i == 2: continue\n    break\n"
+        self.assertEqual(
+            statements(source),
+            ["(while (< i 3) (Block (if (== i 2) (Block continue)) break))"],
+        )
+
+    def test_inline_while(self):
+        self.assertEqual(statements("while 1: break\n"), ["(while 1 (Block break))"])
+
+    def test_jump_outside_of_a_loop_is_rejected(self):
+        for source, keyword in [("break\n", "break"), ("if a: continue\n", "continue")]:
+            with self.subTest(source=source):
+                with self.assertRaisesRegex(Exception, f"'{keyword}' outside of a loop at line 1"):
+                    parse(source)
+
+    def test_function_body_resets_loop_depth(self):
+        with self.assertRaisesRegex(Exception, "'break' outside of a loop at line 2, column 11\\."):
+            parse("while 1:\n    fn f: break\n")
+
+        # Loops inside the function, and jumps after it, still parse
+        source = "while 1:\n    fn f:\n        while 1: break\n    break\n"
+        self.assertEqual(
+            statements(source),
+            ["(while 1 (Block (fn f Arguments (Block (while 1 (Block break)))) break))"],
+        )
+
+    def test_error_columns_count_tabs(self):
+        source = "while 1:\n\tbreak 5\n"
+        for tab_width, col in [(4, 11), (8, 15)]:
+            with self.subTest(tab_width=tab_width):
+                with self.assertRaisesRegex(Exception, f"Unexpected INT after 'break' at line 2, column {col}\\."):
+                    parse(source, tab_width)
+
+    def test_trailing_tokens_after_jump_are_rejected(self):
+        for source, got, keyword, col in [
+            ("while 1:\n    break 5\n", "INT", "break", 11),
+            ("while 1: continue x\n", "ID", "continue", 19),
+        ]:
+            with self.subTest(source=source):
+                with self.assertRaisesRegex(
+                    Exception, f"Unexpected {got} after '{keyword}' at line \\d, column {col}\\."
+                ):
+                    parse(source)
+
+
+class ReturnTest(unittest.TestCase):
+    def test_return_with_and_without_value(self):
+        source = "fn f x:\n    if x: return\n    return x + 1\n"
+        self.assertEqual(
+            statements(source),
+            ["(fn f (Arguments x) (Block (if x (Block return)) (return (+ x 1))))"],
+        )
+
+    def test_trailing_tokens_after_return_are_rejected(self):
+        for source, got, col in [
+            ("fn f:\n    return 1 2\n", "INT", 14),
+            ("fn f: return x y\n", "ID", 16),
+        ]:
+            with self.subTest(source=source):
+                with self.assertRaisesRegex(
+                    Exception, f"Unexpected {got} after 'return' at line \\d, column {col}\\."
+                ):
+                    parse(source)
+
+
+if __name__ == "__main__":
+    unittest.main()
@@ -0,0 +1,30 @@
+import ply.lex as lex
+from core import lex_grammar
+from core.quark_lexer import QuarkLexer
+from core.quark_parser import QuarkParser
+from core.interpreter import QuarkInterpreter
+
+
+def tokens(source, tab_width=4):
+    lexer = QuarkLexer(lex.lex(module=lex_grammar), tab_width)
+    lexer.input(source)
+    return list(lexer.token_stream)
+
+
+def parse(source, tab_width=4):
+    parser = QuarkParser(tokens(source, tab_width))
+    parser.parse()
+    return parser.tree
+
+
+def run(source, args=None):
+    return QuarkInterpreter(args).evaluate(parse(source))
+
+
+# Compact s-expression form of a tree, e.g. "(+ 1 (* 2 3))", so that
+# expected shapes can be written inline in tests
+def sexpr(node):
+    label = str(node.tok.value) if node.tok else str(node.type)
+    if not node.children:
+        return label
+    return f"({label} {' '.join(sexpr(child) for child in node.children)})"
@@ -30,6 +30,6 @@ class TreeViz:
                     self._link(node, node1)
                     self.generate(child, node1)
 
-    def save(self):
-        outf = open("treeviz.dot", "w+")
+    def save(self, path="treeviz.dot"):
+        outf = open(path, "w+")
         self.graph.dot(outf)